@@ -21,6 +21,8 @@
 package encoding
 
 import (
+	"encoding/binary"
+
 	"github.com/m3db/m3db/pool"
 )
 
@@ -106,8 +108,47 @@ func (os *ostream) WriteByte(v byte) {
 
 // WriteBytes writes a byte slice.
 func (os *ostream) WriteBytes(bytes []byte) {
-	for i := 0; i < len(bytes); i++ {
-		os.WriteByte(bytes[i])
+	if len(bytes) == 0 {
+		return
+	}
+
+	if os.pos == 0 || os.pos == 8 {
+		// Byte aligned, append the whole slice directly rather than looping
+		// WriteByte once per byte.
+		if os.bytesPool != nil {
+			os.rawBuffer = pool.AppendBytes(os.rawBuffer, bytes, os.bytesPool)
+		} else {
+			os.rawBuffer = append(os.rawBuffer, bytes...)
+		}
+		os.pos = 8
+		return
+	}
+
+	os.writeBytesUnaligned(bytes)
+}
+
+// writeBytesUnaligned appends bytes when the stream isn't currently byte
+// aligned. Each output byte only needs bits carried over from the previous
+// input byte, so the whole slice can be shifted through a single rolling
+// carry byte in one pass instead of re-deriving the shift/mask on every
+// WriteByte call.
+func (os *ostream) writeBytesUnaligned(bytes []byte) {
+	pos := uint(os.pos)
+	out := make([]byte, len(bytes))
+	carry := os.rawBuffer[os.lastIndex()]
+
+	for i, v := range bytes {
+		out[i] = carry | (v >> pos)
+		carry = v << (8 - pos)
+	}
+
+	os.rawBuffer[os.lastIndex()] = out[0]
+	if os.bytesPool != nil {
+		os.rawBuffer = pool.AppendBytes(os.rawBuffer, out[1:], os.bytesPool)
+		os.rawBuffer = pool.AppendByte(os.rawBuffer, carry, os.bytesPool)
+	} else {
+		os.rawBuffer = append(os.rawBuffer, out[1:]...)
+		os.rawBuffer = append(os.rawBuffer, carry)
 	}
 }
 
@@ -124,10 +165,17 @@ func (os *ostream) WriteBits(v uint64, numBits int) {
 	}
 
 	v <<= uint(64 - numBits)
-	for numBits >= 8 {
-		os.WriteByte(byte(v >> 56))
-		v <<= 8
-		numBits -= 8
+
+	if numByteBits := numBits - numBits%8; numByteBits > 0 {
+		// Emit the byte-aligned portion as a single WriteBytes call (which
+		// itself fast-paths when the stream is aligned) rather than looping
+		// WriteByte a chunk at a time.
+		var scratch [8]byte
+		binary.BigEndian.PutUint64(scratch[:], v)
+		os.WriteBytes(scratch[:numByteBits/8])
+
+		v <<= uint(numByteBits)
+		numBits -= numByteBits
 	}
 
 	for numBits > 0 {
@@ -0,0 +1,121 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encoding
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeBitByBit writes v's lowest numBits into os one bit at a time. It is
+// the ground truth the fast paths in WriteBytes/WriteBits are checked
+// against, independent of their own implementation.
+func writeBitByBit(os *ostream, v uint64, numBits int) {
+	for i := numBits - 1; i >= 0; i-- {
+		os.WriteBit(Bit((v >> uint(i)) & 1))
+	}
+}
+
+func newPrefixedStreams(prefixBits int, seed *rand.Rand) (want, got *ostream) {
+	want = NewOStream(nil, true, nil).(*ostream)
+	for i := 0; i < prefixBits; i++ {
+		want.WriteBit(Bit(seed.Intn(2)))
+	}
+	got = want.Clone().(*ostream)
+	return want, got
+}
+
+func TestWriteBytesMatchesBitByBitAcrossAlignments(t *testing.T) {
+	seed := rand.New(rand.NewSource(42))
+
+	for trial := 0; trial < 200; trial++ {
+		prefixBits := seed.Intn(24)
+		data := make([]byte, 1+seed.Intn(64))
+		seed.Read(data)
+
+		want, got := newPrefixedStreams(prefixBits, seed)
+
+		for _, b := range data {
+			writeBitByBit(want, uint64(b), 8)
+		}
+		got.WriteBytes(data)
+
+		wantBytes, wantPos := want.Rawbytes()
+		gotBytes, gotPos := got.Rawbytes()
+		require.Equal(t, wantPos, gotPos, "trial %d", trial)
+		require.Equal(t, wantBytes, gotBytes, "trial %d", trial)
+	}
+}
+
+func TestWriteBitsMatchesBitByBitAcrossAlignments(t *testing.T) {
+	seed := rand.New(rand.NewSource(7))
+
+	for trial := 0; trial < 200; trial++ {
+		prefixBits := seed.Intn(24)
+		numBits := 1 + seed.Intn(64)
+		v := seed.Uint64()
+
+		want, got := newPrefixedStreams(prefixBits, seed)
+
+		writeBitByBit(want, v, numBits)
+		got.WriteBits(v, numBits)
+
+		wantBytes, wantPos := want.Rawbytes()
+		gotBytes, gotPos := got.Rawbytes()
+		require.Equal(t, wantPos, gotPos, "trial %d", trial)
+		require.Equal(t, wantBytes, gotBytes, "trial %d", trial)
+	}
+}
+
+func BenchmarkOStreamWriteBytes_Aligned(b *testing.B) {
+	data := make([]byte, 1024)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		os := NewOStream(nil, true, nil)
+		os.WriteBytes(data)
+	}
+}
+
+func BenchmarkOStreamWriteBytes_Unaligned(b *testing.B) {
+	data := make([]byte, 1024)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		os := NewOStream(nil, true, nil)
+		os.WriteBit(1)
+		os.WriteBytes(data)
+	}
+}
+
+func BenchmarkOStreamWriteBits_Bulk(b *testing.B) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		os := NewOStream(nil, true, nil)
+		for j := 0; j < 256; j++ {
+			os.WriteBits(0xdeadbeefcafebabe, 61)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"net"
+	"time"
+)
+
+// deadlineConn wraps a net.Conn and resets its read deadline immediately
+// before every read, so a connection that goes idle without sending a
+// complete line is closed by the runtime instead of parking a goroutine
+// forever.
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+	nowFn   func() time.Time
+}
+
+func newDeadlineConn(conn net.Conn, timeout time.Duration, nowFn func() time.Time) net.Conn {
+	if timeout <= 0 {
+		// NB(r): zero timeout means "no deadline", preserve the conn as-is
+		// for backwards compatibility with callers that rely on blocking reads.
+		return conn
+	}
+	return &deadlineConn{Conn: conn, timeout: timeout, nowFn: nowFn}
+}
+
+func (c *deadlineConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(c.nowFn().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(b)
+}
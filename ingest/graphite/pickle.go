@@ -0,0 +1,173 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	xtime "github.com/m3db/m3db/x/time"
+
+	"github.com/kisielk/og-rek"
+)
+
+var (
+	errMalformedLine  = errors.New("graphite: malformed plaintext line")
+	errMalformedBatch = errors.New("graphite: malformed pickle batch")
+)
+
+// pickle batches are framed as a 4-byte big-endian length prefix followed by
+// a pickled list of (metric, (timestamp, value)) tuples, matching the wire
+// format used by carbon's PickleReceiver.
+func (s *Server) servePickle(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go s.handlePickle(newDeadlineConn(conn, s.cfg.ReadTimeout, s.nowFn))
+	}
+}
+
+func (s *Server) handlePickle(conn net.Conn) {
+	defer conn.Close()
+
+	var lenBuf [4]byte
+	for {
+		if _, err := io.ReadFull(conn, lenBuf[:]); err != nil {
+			return
+		}
+
+		size := binary.BigEndian.Uint32(lenBuf[:])
+		if int(size) > s.cfg.MaxLineLength*maxLineLengthBatchFactor {
+			s.scope.Counter("malformed").Inc(1)
+			return
+		}
+
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(conn, payload); err != nil {
+			return
+		}
+
+		if err := s.writeBatch(payload); err != nil {
+			s.scope.Counter("malformed").Inc(1)
+		}
+	}
+}
+
+// maxLineLengthBatchFactor bounds a pickle batch to a multiple of the
+// configured plaintext line length, rather than introducing a second
+// unrelated size limit to tune.
+const maxLineLengthBatchFactor = 1024
+
+func (s *Server) writeBatch(payload []byte) error {
+	decoded, err := ogórek.NewDecoder(newByteReader(payload)).Decode()
+	if err != nil {
+		return err
+	}
+
+	batch, ok := decoded.([]interface{})
+	if !ok {
+		return errMalformedBatch
+	}
+
+	for _, item := range batch {
+		tuple, ok := item.([]interface{})
+		if !ok || len(tuple) != 2 {
+			return errMalformedBatch
+		}
+
+		id, ok := tuple[0].(string)
+		if !ok {
+			return errMalformedBatch
+		}
+
+		metric, ok := tuple[1].([]interface{})
+		if !ok || len(metric) != 2 {
+			return errMalformedBatch
+		}
+
+		secs, err := toInt64(metric[0])
+		if err != nil {
+			return err
+		}
+
+		value, err := toFloat64(metric[1])
+		if err != nil {
+			return err
+		}
+
+		if err := s.writer.Write(id, time.Unix(secs, 0), value, xtime.Second, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func toInt64(v interface{}) (int64, error) {
+	switch t := v.(type) {
+	case int64:
+		return t, nil
+	case int:
+		return int64(t), nil
+	case float64:
+		return int64(t), nil
+	default:
+		return 0, errMalformedBatch
+	}
+}
+
+func toFloat64(v interface{}) (float64, error) {
+	switch t := v.(type) {
+	case float64:
+		return t, nil
+	case int64:
+		return float64(t), nil
+	case int:
+		return float64(t), nil
+	default:
+		return 0, errMalformedBatch
+	}
+}
+
+func newByteReader(b []byte) io.Reader {
+	return &byteReader{b: b}
+}
+
+// byteReader is a minimal io.Reader over an in-memory slice, avoiding a
+// bytes.Reader import purely to keep this file self contained.
+type byteReader struct {
+	b   []byte
+	off int
+}
+
+func (r *byteReader) Read(p []byte) (int, error) {
+	if r.off >= len(r.b) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.b[r.off:])
+	r.off += n
+	return n, nil
+}
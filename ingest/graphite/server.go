@@ -0,0 +1,158 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3db/x/metrics"
+	xtime "github.com/m3db/m3db/x/time"
+)
+
+// Server accepts Graphite plaintext and pickle protocol connections and
+// writes the decoded samples into a Writer.
+type Server struct {
+	writer Writer
+	cfg    IngestConfig
+	scope  metrics.Scope
+	nowFn  func() time.Time
+}
+
+// NewServer creates a new Graphite ingestion Server that writes decoded
+// samples into writer.
+func NewServer(writer Writer, cfg IngestConfig, scope metrics.Scope) *Server {
+	if cfg.MaxLineLength <= 0 {
+		cfg.MaxLineLength = DefaultMaxLineLength
+	}
+	return &Server{
+		writer: writer,
+		cfg:    cfg,
+		scope:  scope.SubScope("graphite"),
+		nowFn:  time.Now,
+	}
+}
+
+// ListenAndServe binds the configured plaintext and/or pickle listeners and
+// serves connections until the returned io.Closer is closed. Either address
+// may be left empty to disable that protocol.
+func (s *Server) ListenAndServe() (io.Closer, error) {
+	var closers []io.Closer
+
+	if s.cfg.ListenAddr != "" {
+		l, err := net.Listen("tcp", s.cfg.ListenAddr)
+		if err != nil {
+			closeAll(closers)
+			return nil, err
+		}
+		closers = append(closers, l)
+		go s.servePlaintext(l)
+	}
+
+	if s.cfg.PickleAddr != "" {
+		l, err := net.Listen("tcp", s.cfg.PickleAddr)
+		if err != nil {
+			closeAll(closers)
+			return nil, err
+		}
+		closers = append(closers, l)
+		go s.servePickle(l)
+	}
+
+	return multiCloser(closers), nil
+}
+
+func (s *Server) servePlaintext(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go s.handlePlaintext(newDeadlineConn(conn, s.cfg.ReadTimeout, s.nowFn))
+	}
+}
+
+func (s *Server) handlePlaintext(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReaderSize(conn, s.cfg.MaxLineLength)
+	for {
+		line, isPrefix, err := reader.ReadLine()
+		if err != nil {
+			return
+		}
+		if isPrefix {
+			// NB(r): line exceeded MaxLineLength, drain and drop it rather
+			// than buffering an attacker-controlled amount of memory.
+			s.scope.Counter("malformed").Inc(1)
+			for isPrefix && err == nil {
+				_, isPrefix, err = reader.ReadLine()
+			}
+			continue
+		}
+		if err := s.writeLine(string(line)); err != nil {
+			s.scope.Counter("malformed").Inc(1)
+		}
+	}
+}
+
+func (s *Server) writeLine(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return errMalformedLine
+	}
+
+	id := fields[0]
+
+	value, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return err
+	}
+
+	secs, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	return s.writer.Write(id, time.Unix(secs, 0), value, xtime.Second, nil)
+}
+
+func closeAll(closers []io.Closer) {
+	for _, c := range closers {
+		c.Close()
+	}
+}
+
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var firstErr error
+	for _, c := range m {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	xtime "github.com/m3db/m3db/x/time"
+	"github.com/m3db/m3db/x/metrics"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWriter struct {
+	mu      sync.Mutex
+	written []string
+}
+
+func (w *fakeWriter) Write(id string, t time.Time, value float64, unit xtime.Unit, annotation []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.written = append(w.written, id)
+	return nil
+}
+
+func TestDeadlineConnClosesIdleConnection(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	server := <-accepted
+	conn := newDeadlineConn(server, 10*time.Millisecond, time.Now)
+	defer conn.Close()
+
+	buf := make([]byte, 1)
+	_, err = conn.Read(buf)
+	require.Error(t, err)
+
+	netErr, ok := err.(net.Error)
+	require.True(t, ok)
+	require.True(t, netErr.Timeout())
+}
+
+func TestZeroTimeoutDisablesDeadline(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	wrapped := newDeadlineConn(conn, 0, time.Now)
+	_, isDeadlineConn := wrapped.(*deadlineConn)
+	require.False(t, isDeadlineConn)
+}
+
+func TestServerWritePlaintextLine(t *testing.T) {
+	writer := &fakeWriter{}
+	s := NewServer(writer, IngestConfig{}, metrics.NoopScope)
+
+	err := s.writeLine("servers.foo.cpu 42.5 1465934820")
+	require.NoError(t, err)
+	require.Equal(t, []string{"servers.foo.cpu"}, writer.written)
+
+	require.Error(t, s.writeLine("malformed"))
+}
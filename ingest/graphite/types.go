@@ -0,0 +1,58 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package graphite implements TCP listeners for the classic Graphite
+// plaintext and Python pickle batched ingestion protocols.
+package graphite
+
+import (
+	"time"
+
+	xtime "github.com/m3db/m3db/x/time"
+)
+
+// DefaultMaxLineLength is the default maximum accepted length, in bytes, of
+// a single plaintext metric line.
+const DefaultMaxLineLength = 4096
+
+// IngestConfig configures the Graphite plaintext and pickle listeners.
+type IngestConfig struct {
+	// ListenAddr is the address the plaintext listener binds to, empty disables it.
+	ListenAddr string
+
+	// PickleAddr is the address the pickle listener binds to, empty disables it.
+	PickleAddr string
+
+	// ReadTimeout bounds how long an accepted connection may sit idle before
+	// it is closed. A zero value disables the deadline, preserving the
+	// previous behavior of blocking reads indefinitely.
+	ReadTimeout time.Duration
+
+	// MaxLineLength bounds the length of a single plaintext line so a
+	// malformed or malicious sender cannot force unbounded buffering. Zero
+	// defaults to DefaultMaxLineLength.
+	MaxLineLength int
+}
+
+// Writer accepts a single decoded metric sample and routes it into the
+// storage write path. The database satisfies this interface.
+type Writer interface {
+	Write(id string, t time.Time, value float64, unit xtime.Unit, annotation []byte) error
+}
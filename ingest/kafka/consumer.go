@@ -0,0 +1,226 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/m3db/m3db/interfaces/m3db"
+	"github.com/m3db/m3db/x/metrics"
+
+	"github.com/Shopify/sarama"
+)
+
+var errNoMessageDecoderFn = errors.New("kafka: MessageDecoderFn must be set")
+
+const (
+	// defaultDrainBatchSize is the default IngestConfig.DrainBatchSize.
+	defaultDrainBatchSize = 500
+
+	// defaultDrainInterval is the default IngestConfig.DrainInterval.
+	defaultDrainInterval = time.Second
+)
+
+// Drainer flushes a node's write buffer and reports whether the flush
+// succeeded, so a partition's offsets are only committed once the samples
+// that produced them are durable.
+type Drainer interface {
+	Drain() error
+}
+
+// Consumer consumes metric batches off a set of Kafka topics via a Sarama
+// consumer group and writes the decoded samples into per-series
+// m3db.Encoders by routing each sample through writer.
+type Consumer struct {
+	opts          m3db.DatabaseOptions
+	cfg           IngestConfig
+	writer        Writer
+	scope         metrics.Scope
+	drainer       Drainer
+	group         sarama.ConsumerGroup
+	drainBatch    int
+	drainInterval time.Duration
+}
+
+// NewConsumer creates a new Kafka ingestion Consumer. It returns an error if
+// cfg.MessageDecoderFn is unset or the consumer group cannot be created.
+func NewConsumer(opts m3db.DatabaseOptions, cfg IngestConfig, writer Writer, drainer Drainer) (*Consumer, error) {
+	if cfg.MessageDecoderFn == nil {
+		return nil, errNoMessageDecoderFn
+	}
+
+	saramaCfg := sarama.NewConfig()
+	saramaCfg.Version = sarama.V2_0_0_0
+	saramaCfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+	saramaCfg.Consumer.Return.Errors = true
+
+	group, err := sarama.NewConsumerGroup(cfg.Brokers, cfg.GroupID, saramaCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	drainBatch := cfg.DrainBatchSize
+	if drainBatch <= 0 {
+		drainBatch = defaultDrainBatchSize
+	}
+	drainInterval := cfg.DrainInterval
+	if drainInterval <= 0 {
+		drainInterval = defaultDrainInterval
+	}
+
+	return &Consumer{
+		opts:          opts,
+		cfg:           cfg,
+		writer:        writer,
+		scope:         opts.GetMetricsScope().SubScope("kafka"),
+		drainer:       drainer,
+		group:         group,
+		drainBatch:    drainBatch,
+		drainInterval: drainInterval,
+	}, nil
+}
+
+// Run joins the consumer group and consumes until ctx is canceled or an
+// unrecoverable error occurs. Sarama's Consume returns at the end of every
+// group generation (e.g. on a rebalance), so Run calls it again in a loop
+// until ctx is done.
+func (c *Consumer) Run(ctx context.Context) error {
+	go c.logErrors(ctx)
+	for ctx.Err() == nil {
+		if err := c.group.Consume(ctx, c.cfg.Topics, c); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// Close releases the underlying consumer group's resources.
+func (c *Consumer) Close() error {
+	return c.group.Close()
+}
+
+func (c *Consumer) logErrors(ctx context.Context) {
+	for {
+		select {
+		case err, ok := <-c.group.Errors():
+			if !ok {
+				return
+			}
+			c.opts.GetLogger().Errorf("kafka consumer group error: %v", err)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Setup implements sarama.ConsumerGroupHandler.
+func (c *Consumer) Setup(sarama.ConsumerGroupSession) error { return nil }
+
+// Cleanup implements sarama.ConsumerGroupHandler.
+func (c *Consumer) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+// ConsumeClaim implements sarama.ConsumerGroupHandler. Sarama invokes this
+// once per claimed partition in its own goroutine, so each partition worker
+// writes through its own call into this method without any cross-partition
+// contention; routing by id on write lets the storage layer own the
+// per-series encoder for id rather than this method multiplexing samples for
+// every id on the partition into one shared encoder.
+//
+// Writes are drained in batches of c.drainBatch messages, or whenever
+// c.drainInterval elapses with a partial batch pending, rather than once per
+// message: Drainer flushes a node's entire write buffer, the same node-wide
+// operation bufferDrain runs on a one-minute ticker elsewhere, so draining
+// per-sample would serialize every partition worker's throughput to one
+// drain round-trip per message.
+func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	lag := c.scope.Gauge("lag")
+
+	ticker := time.NewTicker(c.drainInterval)
+	defer ticker.Stop()
+
+	pending := make([]*sarama.ConsumerMessage, 0, c.drainBatch)
+	messages := claim.Messages()
+
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return c.drainAndMark(session, pending)
+			}
+
+			id, t, value, unit, annotation, err := c.cfg.MessageDecoderFn(msg.Value)
+			if err != nil {
+				c.scope.Counter("malformed").Inc(1)
+				continue
+			}
+
+			if err := c.writer.Write(id, t, value, unit, annotation); err != nil {
+				c.scope.Counter("malformed").Inc(1)
+				continue
+			}
+
+			pending = append(pending, msg)
+			lag.Update(float64(claim.HighWaterMarkOffset() - msg.Offset))
+
+			if len(pending) >= c.drainBatch {
+				if err := c.drainAndMark(session, pending); err != nil {
+					return err
+				}
+				pending = pending[:0]
+			}
+
+		case <-ticker.C:
+			if len(pending) == 0 {
+				continue
+			}
+			if err := c.drainAndMark(session, pending); err != nil {
+				return err
+			}
+			pending = pending[:0]
+		}
+	}
+}
+
+// drainAndMark drains the write buffer once for the whole batch of pending
+// messages and, only if that succeeds, marks every one of them. Stopping
+// short on a drain failure rather than marking anyway matters: Sarama only
+// tracks one committed offset per partition, so marking a later message
+// would implicitly commit past this undrained batch and lose it on the next
+// rebalance/restart.
+func (c *Consumer) drainAndMark(session sarama.ConsumerGroupSession, pending []*sarama.ConsumerMessage) error {
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if c.drainer != nil {
+		if err := c.drainer.Drain(); err != nil {
+			return err
+		}
+	}
+
+	for _, msg := range pending {
+		session.MarkMessage(msg, "")
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/x/metrics"
+	xtime "github.com/m3db/m3db/x/time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWriter struct {
+	mu      sync.Mutex
+	written []string
+}
+
+func (w *fakeWriter) Write(id string, t time.Time, value float64, unit xtime.Unit, annotation []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.written = append(w.written, id)
+	return nil
+}
+
+func (w *fakeWriter) ids() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.written...)
+}
+
+type fakeDrainer struct {
+	mu       sync.Mutex
+	calls    int
+	failFrom int
+}
+
+func (d *fakeDrainer) Drain() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls++
+	if d.failFrom > 0 && d.calls >= d.failFrom {
+		return errors.New("drain failed")
+	}
+	return nil
+}
+
+// fakeClaim implements sarama.ConsumerGroupClaim for a single fake partition.
+type fakeClaim struct {
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Topic() string              { return "metrics" }
+func (c *fakeClaim) Partition() int32           { return 0 }
+func (c *fakeClaim) InitialOffset() int64       { return 0 }
+func (c *fakeClaim) HighWaterMarkOffset() int64 { return 0 }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.messages
+}
+
+// fakeSession implements sarama.ConsumerGroupSession, recording every
+// message ConsumeClaim marks.
+type fakeSession struct {
+	mu     sync.Mutex
+	marked []int64
+}
+
+func (s *fakeSession) Claims() map[string][]int32 { return nil }
+func (s *fakeSession) MemberID() string           { return "fake" }
+func (s *fakeSession) GenerationID() int32        { return 0 }
+func (s *fakeSession) MarkOffset(topic string, partition int32, offset int64, metadata string) {
+}
+func (s *fakeSession) Commit() {}
+func (s *fakeSession) ResetOffset(topic string, partition int32, offset int64, metadata string) {
+}
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.marked = append(s.marked, msg.Offset)
+}
+func (s *fakeSession) Context() context.Context { return context.Background() }
+
+func (s *fakeSession) markedOffsets() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64(nil), s.marked...)
+}
+
+func newTestConsumer(writer Writer, drainer Drainer, drainBatch int) *Consumer {
+	return &Consumer{
+		cfg: IngestConfig{
+			MessageDecoderFn: func(value []byte) (string, time.Time, float64, xtime.Unit, []byte, error) {
+				return string(value), time.Unix(1465934820, 0), 42, xtime.Second, nil, nil
+			},
+		},
+		writer:        writer,
+		scope:         metrics.NoopScope,
+		drainer:       drainer,
+		drainBatch:    drainBatch,
+		drainInterval: time.Hour,
+	}
+}
+
+func TestConsumeClaimRoutesEverySampleToItsOwnSeries(t *testing.T) {
+	writer := &fakeWriter{}
+	c := newTestConsumer(writer, &fakeDrainer{}, 3)
+
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 3)}
+	claim.messages <- &sarama.ConsumerMessage{Value: []byte("series.a"), Offset: 0}
+	claim.messages <- &sarama.ConsumerMessage{Value: []byte("series.b"), Offset: 1}
+	claim.messages <- &sarama.ConsumerMessage{Value: []byte("series.c"), Offset: 2}
+	close(claim.messages)
+
+	session := &fakeSession{}
+	require.NoError(t, c.ConsumeClaim(session, claim))
+
+	require.Equal(t, []string{"series.a", "series.b", "series.c"}, writer.ids())
+	require.Equal(t, []int64{0, 1, 2}, session.markedOffsets())
+}
+
+func TestConsumeClaimStopsPartitionOnDrainFailure(t *testing.T) {
+	writer := &fakeWriter{}
+	// drainBatch of 1 drains after every message; the second drain fails.
+	c := newTestConsumer(writer, &fakeDrainer{failFrom: 2}, 1)
+
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 2)}
+	claim.messages <- &sarama.ConsumerMessage{Value: []byte("series.a"), Offset: 0}
+	claim.messages <- &sarama.ConsumerMessage{Value: []byte("series.b"), Offset: 1}
+
+	session := &fakeSession{}
+	err := c.ConsumeClaim(session, claim)
+	require.Error(t, err)
+
+	// Only the batch that drained successfully was marked; the second
+	// message must not be marked, or its offset would be committed even
+	// though the batch holding it was never drained.
+	require.Equal(t, []int64{0}, session.markedOffsets())
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package kafka consumes metric batches off one or more Kafka topics using a
+// Sarama consumer group and writes the decoded samples into per-series
+// m3db.Encoders via a Writer.
+package kafka
+
+import (
+	"time"
+
+	xtime "github.com/m3db/m3db/x/time"
+)
+
+// MessageDecoderFn decodes a raw Kafka message value into a single sample.
+type MessageDecoderFn func(value []byte) (id string, t time.Time, value64 float64, unit xtime.Unit, annotation []byte, err error)
+
+// Writer accepts a single decoded metric sample and routes it, by id, into
+// the storage write path, which owns the per-series encoder for id. The
+// database satisfies this interface.
+type Writer interface {
+	Write(id string, t time.Time, value float64, unit xtime.Unit, annotation []byte) error
+}
+
+// IngestConfig configures the Kafka consumer group ingestion pipeline.
+type IngestConfig struct {
+	// Brokers is the list of seed Kafka broker addresses, empty disables ingestion.
+	Brokers []string
+
+	// Topics is the set of topics the consumer group subscribes to.
+	Topics []string
+
+	// GroupID is the Kafka consumer group ID, shared across all m3db nodes
+	// ingesting the same topics so partitions are divided between them.
+	GroupID string
+
+	// MessageDecoderFn decodes a raw message into a sample, required when
+	// any of Brokers/Topics is set.
+	MessageDecoderFn MessageDecoderFn
+
+	// DrainBatchSize is how many decoded samples are written between
+	// Drainer.Drain calls, zero uses defaultDrainBatchSize. Offsets for a
+	// batch are only marked once that batch's Drain call succeeds.
+	DrainBatchSize int
+
+	// DrainInterval bounds how long a partial batch waits for
+	// DrainBatchSize to fill before it is drained anyway, zero uses
+	// defaultDrainInterval.
+	DrainInterval time.Duration
+}
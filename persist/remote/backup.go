@@ -0,0 +1,122 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"time"
+
+	"github.com/m3db/m3db/x/metrics"
+)
+
+// SealedBlock describes a local fileset that is sealed and eligible for backup.
+type SealedBlock struct {
+	Namespace  string
+	Shard      uint32
+	BlockStart time.Time
+	Files      []BlockFile
+}
+
+// ListSealedBlocksFn returns the sealed blocks currently on local disk.
+type ListSealedBlocksFn func() ([]SealedBlock, error)
+
+// Backuper periodically uploads sealed blocks that are not yet in the
+// manifest to a BackupStore, retrying failed uploads up to MaxRetries times.
+type Backuper struct {
+	store    BackupStore
+	manifest *Manifest
+	listFn   ListSealedBlocksFn
+	cfg      BackupConfig
+	scope    metrics.Scope
+	nowFn    func() time.Time
+}
+
+// NewBackuper creates a new Backuper.
+func NewBackuper(store BackupStore, manifest *Manifest, listFn ListSealedBlocksFn, cfg BackupConfig, scope metrics.Scope) *Backuper {
+	return &Backuper{
+		store:    store,
+		manifest: manifest,
+		listFn:   listFn,
+		cfg:      cfg,
+		scope:    scope.SubScope("backup"),
+		nowFn:    time.Now,
+	}
+}
+
+// Run blocks, uploading sealed blocks on cfg.Interval, until stopC is closed.
+// A non-positive Interval makes Run a no-op so backups can be disabled
+// without callers needing to special-case construction.
+func (b *Backuper) Run(stopC <-chan struct{}) {
+	if b.cfg.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(b.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.backupOnce()
+		case <-stopC:
+			return
+		}
+	}
+}
+
+func (b *Backuper) backupOnce() {
+	blocks, err := b.listFn()
+	if err != nil {
+		b.scope.Counter("list.errors").Inc(1)
+		return
+	}
+
+	for _, block := range blocks {
+		checksum := ChecksumFiles(block.Files)
+		if b.manifest.IsUploaded(block.Namespace, block.Shard, block.BlockStart, checksum) {
+			continue
+		}
+
+		if err := b.uploadWithRetry(block); err != nil {
+			b.scope.Counter("upload.errors").Inc(1)
+			continue
+		}
+
+		b.manifest.MarkUploaded(block.Namespace, block.Shard, block.BlockStart, checksum)
+		b.scope.Counter("upload.success").Inc(1)
+	}
+}
+
+func (b *Backuper) uploadWithRetry(block SealedBlock) error {
+	maxRetries := b.cfg.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		start := b.nowFn()
+		if err = b.store.PutBlock(block.Namespace, block.Shard, block.BlockStart, block.Files); err == nil {
+			b.scope.Timer("upload.latency").Record(b.nowFn().Sub(start))
+			return nil
+		}
+	}
+	return err
+}
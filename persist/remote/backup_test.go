@@ -0,0 +1,118 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/x/metrics"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUploadStore is a BackupStore whose PutBlock can be made to fail a
+// fixed number of times before succeeding, to exercise Backuper's retry path.
+type fakeUploadStore struct {
+	BackupStore
+
+	mu        sync.Mutex
+	failTimes int
+	puts      []SealedBlock
+}
+
+func (s *fakeUploadStore) PutBlock(namespace string, shard uint32, blockStart time.Time, files []BlockFile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failTimes > 0 {
+		s.failTimes--
+		return errors.New("put block failed")
+	}
+
+	s.puts = append(s.puts, SealedBlock{Namespace: namespace, Shard: shard, BlockStart: blockStart, Files: files})
+	return nil
+}
+
+func (s *fakeUploadStore) numPuts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.puts)
+}
+
+func listOneBlockFn(block SealedBlock) ListSealedBlocksFn {
+	return func() ([]SealedBlock, error) {
+		return []SealedBlock{block}, nil
+	}
+}
+
+func TestBackuperUploadsAfterTransientRetries(t *testing.T) {
+	block := SealedBlock{
+		Namespace:  "metrics",
+		Shard:      1,
+		BlockStart: time.Unix(1465934820, 0),
+		Files:      []BlockFile{{Path: "data.db", Checksum: 7}},
+	}
+	store := &fakeUploadStore{failTimes: 2}
+	manifest := NewManifest()
+
+	b := NewBackuper(store, manifest, listOneBlockFn(block), BackupConfig{MaxRetries: 2}, metrics.NoopScope)
+	b.backupOnce()
+
+	require.Equal(t, 1, store.numPuts())
+	require.True(t, manifest.IsUploaded(block.Namespace, block.Shard, block.BlockStart, ChecksumFiles(block.Files)))
+}
+
+func TestBackuperSkipsBlockWhenRetriesExhausted(t *testing.T) {
+	block := SealedBlock{
+		Namespace:  "metrics",
+		Shard:      1,
+		BlockStart: time.Unix(1465934820, 0),
+		Files:      []BlockFile{{Path: "data.db", Checksum: 7}},
+	}
+	store := &fakeUploadStore{failTimes: 10}
+	manifest := NewManifest()
+
+	b := NewBackuper(store, manifest, listOneBlockFn(block), BackupConfig{MaxRetries: 1}, metrics.NoopScope)
+	b.backupOnce()
+
+	require.Equal(t, 0, store.numPuts())
+	require.False(t, manifest.IsUploaded(block.Namespace, block.Shard, block.BlockStart, ChecksumFiles(block.Files)))
+}
+
+func TestBackuperSkipsBlockAlreadyInManifest(t *testing.T) {
+	block := SealedBlock{
+		Namespace:  "metrics",
+		Shard:      1,
+		BlockStart: time.Unix(1465934820, 0),
+		Files:      []BlockFile{{Path: "data.db", Checksum: 7}},
+	}
+	store := &fakeUploadStore{}
+	manifest := NewManifest()
+	manifest.MarkUploaded(block.Namespace, block.Shard, block.BlockStart, ChecksumFiles(block.Files))
+
+	b := NewBackuper(store, manifest, listOneBlockFn(block), BackupConfig{}, metrics.NoopScope)
+	b.backupOnce()
+
+	require.Equal(t, 0, store.numPuts())
+}
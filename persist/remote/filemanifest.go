@@ -0,0 +1,103 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// FileManifestName is the sidecar object/file every BackupStore driver
+	// writes alongside a block's data files, recording the checksum each
+	// file was uploaded with so GetBlock can verify downloaded bytes are
+	// intact rather than silently restoring a truncated file.
+	FileManifestName = "manifest.crc"
+
+	// BlockManifestName is the sidecar object/file recording the block's
+	// aggregate checksum (see ChecksumFiles), so a Manifest can be rebuilt
+	// from what a BackupStore already has without re-downloading the block.
+	BlockManifestName = "block.crc"
+)
+
+// EncodeFileManifest serializes the per-file checksums of files as
+// "name\tchecksum" lines, one per file, keyed by base file name.
+func EncodeFileManifest(files []BlockFile) []byte {
+	var buf bytes.Buffer
+	for _, f := range files {
+		fmt.Fprintf(&buf, "%s\t%d\n", filepath.Base(f.Path), f.Checksum)
+	}
+	return buf.Bytes()
+}
+
+// DecodeFileManifest parses a manifest previously produced by EncodeFileManifest.
+func DecodeFileManifest(data []byte) (map[string]uint32, error) {
+	checksums := make(map[string]uint32)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("remote: malformed manifest line %q", line)
+		}
+
+		checksum, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("remote: malformed checksum in line %q: %v", line, err)
+		}
+
+		checksums[parts[0]] = uint32(checksum)
+	}
+
+	return checksums, scanner.Err()
+}
+
+// EncodeBlockChecksum serializes a block's aggregate checksum.
+func EncodeBlockChecksum(checksum uint32) []byte {
+	return []byte(strconv.FormatUint(uint64(checksum), 10))
+}
+
+// DecodeBlockChecksum parses a checksum previously produced by EncodeBlockChecksum.
+func DecodeBlockChecksum(data []byte) (uint32, error) {
+	v, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("remote: malformed block checksum: %v", err)
+	}
+	return uint32(v), nil
+}
+
+// VerifyChecksum returns an error if data's checksum doesn't match expected.
+func VerifyChecksum(name string, data []byte, expected uint32) error {
+	if actual := crc32.ChecksumIEEE(data); actual != expected {
+		return fmt.Errorf("remote: checksum mismatch restoring %q: expected %d, got %d", name, expected, actual)
+	}
+	return nil
+}
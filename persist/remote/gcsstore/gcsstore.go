@@ -0,0 +1,208 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package gcsstore implements a remote.BackupStore backed by Google Cloud Storage.
+package gcsstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3db/persist/remote"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+type store struct {
+	bucket *storage.BucketHandle
+	prefix string
+	ctx    context.Context
+}
+
+// New creates a remote.BackupStore backed by the GCS bucket, with all object
+// names namespaced under prefix.
+func New(ctx context.Context, client *storage.Client, bucket, prefix string) remote.BackupStore {
+	return &store{
+		bucket: client.Bucket(bucket),
+		prefix: strings.TrimSuffix(prefix, "/"),
+		ctx:    ctx,
+	}
+}
+
+func (s *store) objectName(namespace string, shard uint32, blockStart time.Time, name string) string {
+	return fmt.Sprintf("%s/%s/%d/%d/%s", s.prefix, namespace, shard, blockStart.UnixNano(), name)
+}
+
+func (s *store) PutBlock(namespace string, shard uint32, blockStart time.Time, files []remote.BlockFile) error {
+	for _, f := range files {
+		fd, err := os.Open(f.Path)
+		if err != nil {
+			return err
+		}
+
+		w := s.bucket.Object(s.objectName(namespace, shard, blockStart, filepath.Base(f.Path))).NewWriter(s.ctx)
+		_, copyErr := io.Copy(w, fd)
+		closeErr := w.Close()
+		fd.Close()
+
+		if copyErr != nil {
+			return copyErr
+		}
+		if closeErr != nil {
+			return closeErr
+		}
+	}
+
+	if err := s.putObject(s.objectName(namespace, shard, blockStart, remote.FileManifestName), remote.EncodeFileManifest(files)); err != nil {
+		return err
+	}
+	return s.putObject(s.objectName(namespace, shard, blockStart, remote.BlockManifestName), remote.EncodeBlockChecksum(remote.ChecksumFiles(files)))
+}
+
+func (s *store) putObject(name string, data []byte) error {
+	w := s.bucket.Object(name).NewWriter(s.ctx)
+	_, writeErr := w.Write(data)
+	closeErr := w.Close()
+	if writeErr != nil {
+		return writeErr
+	}
+	return closeErr
+}
+
+func (s *store) getObject(name string) ([]byte, error) {
+	r, err := s.bucket.Object(name).NewReader(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func (s *store) BlockChecksum(namespace string, shard uint32, blockStart time.Time) (uint32, error) {
+	data, err := s.getObject(s.objectName(namespace, shard, blockStart, remote.BlockManifestName))
+	if err != nil {
+		return 0, err
+	}
+	return remote.DecodeBlockChecksum(data)
+}
+
+func (s *store) ListBlocks(namespace string, shard uint32) ([]time.Time, error) {
+	prefix := fmt.Sprintf("%s/%s/%d/", s.prefix, namespace, shard)
+
+	seen := make(map[int64]struct{})
+	var blockStarts []time.Time
+
+	it := s.bucket.Objects(s.ctx, &storage.Query{Prefix: prefix})
+	for {
+		obj, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parts := strings.Split(strings.TrimPrefix(obj.Name, prefix), "/")
+		if len(parts) < 2 {
+			continue
+		}
+		nanos, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if _, ok := seen[nanos]; ok {
+			continue
+		}
+		seen[nanos] = struct{}{}
+		blockStarts = append(blockStarts, time.Unix(0, nanos))
+	}
+
+	return blockStarts, nil
+}
+
+func (s *store) GetBlock(namespace string, shard uint32, blockStart time.Time, destDir string) ([]string, error) {
+	prefix := fmt.Sprintf("%s/%s/%d/%d/", s.prefix, namespace, shard, blockStart.UnixNano())
+
+	manifestData, err := s.getObject(s.objectName(namespace, shard, blockStart, remote.FileManifestName))
+	if err != nil {
+		return nil, err
+	}
+	checksums, err := remote.DecodeFileManifest(manifestData)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, err
+	}
+
+	var restored []string
+	it := s.bucket.Objects(s.ctx, &storage.Query{Prefix: prefix})
+	for {
+		obj, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimPrefix(obj.Name, prefix)
+		if name == remote.FileManifestName || name == remote.BlockManifestName {
+			continue
+		}
+
+		r, err := s.bucket.Object(obj.Name).NewReader(s.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		checksum, ok := checksums[name]
+		if !ok {
+			return nil, fmt.Errorf("gcsstore: no manifest entry for %q", name)
+		}
+		if err := remote.VerifyChecksum(name, data, checksum); err != nil {
+			return nil, err
+		}
+
+		destPath := filepath.Join(destDir, name)
+		if err := ioutil.WriteFile(destPath, data, 0644); err != nil {
+			return nil, err
+		}
+
+		restored = append(restored, destPath)
+	}
+
+	return restored, nil
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package localstore implements a remote.BackupStore backed by a local
+// directory, used in tests and single-node setups in place of S3/GCS.
+package localstore
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/m3db/m3db/persist/remote"
+)
+
+type store struct {
+	rootDir string
+}
+
+// New creates a remote.BackupStore rooted at rootDir. rootDir is created if
+// it does not already exist.
+func New(rootDir string) (remote.BackupStore, error) {
+	if err := os.MkdirAll(rootDir, 0755); err != nil {
+		return nil, err
+	}
+	return &store{rootDir: rootDir}, nil
+}
+
+func (s *store) blockDir(namespace string, shard uint32, blockStart time.Time) string {
+	return filepath.Join(s.rootDir, namespace, strconv.Itoa(int(shard)), strconv.FormatInt(blockStart.UnixNano(), 10))
+}
+
+func (s *store) PutBlock(namespace string, shard uint32, blockStart time.Time, files []remote.BlockFile) error {
+	dir := s.blockDir(namespace, shard, blockStart)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		src, err := os.Open(f.Path)
+		if err != nil {
+			return err
+		}
+
+		dst, err := os.Create(filepath.Join(dir, filepath.Base(f.Path)))
+		if err != nil {
+			src.Close()
+			return err
+		}
+
+		_, err = io.Copy(dst, src)
+		src.Close()
+		dst.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, remote.FileManifestName), remote.EncodeFileManifest(files), 0644); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, remote.BlockManifestName), remote.EncodeBlockChecksum(remote.ChecksumFiles(files)), 0644)
+}
+
+func (s *store) BlockChecksum(namespace string, shard uint32, blockStart time.Time) (uint32, error) {
+	data, err := ioutil.ReadFile(filepath.Join(s.blockDir(namespace, shard, blockStart), remote.BlockManifestName))
+	if err != nil {
+		return 0, err
+	}
+	return remote.DecodeBlockChecksum(data)
+}
+
+func (s *store) ListBlocks(namespace string, shard uint32) ([]time.Time, error) {
+	dir := filepath.Join(s.rootDir, namespace, strconv.Itoa(int(shard)))
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	blockStarts := make([]time.Time, 0, len(entries))
+	for _, entry := range entries {
+		nanos, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("localstore: malformed block dir %q: %v", entry.Name(), err)
+		}
+		blockStarts = append(blockStarts, time.Unix(0, nanos))
+	}
+
+	sort.Slice(blockStarts, func(i, j int) bool { return blockStarts[i].Before(blockStarts[j]) })
+	return blockStarts, nil
+}
+
+func (s *store) GetBlock(namespace string, shard uint32, blockStart time.Time, destDir string) ([]string, error) {
+	dir := s.blockDir(namespace, shard, blockStart)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestData, err := ioutil.ReadFile(filepath.Join(dir, remote.FileManifestName))
+	if err != nil {
+		return nil, err
+	}
+	checksums, err := remote.DecodeFileManifest(manifestData)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, err
+	}
+
+	restored := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == remote.FileManifestName || name == remote.BlockManifestName {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		checksum, ok := checksums[name]
+		if !ok {
+			return nil, fmt.Errorf("localstore: no manifest entry for %q", name)
+		}
+		if err := remote.VerifyChecksum(name, data, checksum); err != nil {
+			return nil, err
+		}
+
+		destPath := filepath.Join(destDir, name)
+		if err := ioutil.WriteFile(destPath, data, 0644); err != nil {
+			return nil, err
+		}
+
+		restored = append(restored, destPath)
+	}
+
+	return restored, nil
+}
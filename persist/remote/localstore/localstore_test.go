@@ -0,0 +1,120 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package localstore
+
+import (
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/persist/remote"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutListGetBlockRoundTrip(t *testing.T) {
+	root, err := ioutil.TempDir("", "localstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	srcDir, err := ioutil.TempDir("", "localstore-src")
+	require.NoError(t, err)
+	defer os.RemoveAll(srcDir)
+
+	srcFile := filepath.Join(srcDir, "fileset-data.db")
+	data := []byte("some-block-data")
+	require.NoError(t, ioutil.WriteFile(srcFile, data, 0644))
+
+	s, err := New(root)
+	require.NoError(t, err)
+
+	blockStart := time.Unix(1465934820, 0)
+	checksum := crc32.ChecksumIEEE(data)
+	files := []remote.BlockFile{{Path: srcFile, Checksum: checksum}}
+
+	require.NoError(t, s.PutBlock("metrics", 3, blockStart, files))
+
+	blockStarts, err := s.ListBlocks("metrics", 3)
+	require.NoError(t, err)
+	require.Len(t, blockStarts, 1)
+	require.True(t, blockStart.Equal(blockStarts[0]))
+
+	blockChecksum, err := s.BlockChecksum("metrics", 3, blockStart)
+	require.NoError(t, err)
+	require.Equal(t, remote.ChecksumFiles(files), blockChecksum)
+
+	destDir, err := ioutil.TempDir("", "localstore-dest")
+	require.NoError(t, err)
+	defer os.RemoveAll(destDir)
+
+	restored, err := s.GetBlock("metrics", 3, blockStart, destDir)
+	require.NoError(t, err)
+	require.Len(t, restored, 1)
+
+	contents, err := ioutil.ReadFile(restored[0])
+	require.NoError(t, err)
+	require.Equal(t, "some-block-data", string(contents))
+}
+
+func TestGetBlockFailsOnChecksumMismatch(t *testing.T) {
+	root, err := ioutil.TempDir("", "localstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	srcDir, err := ioutil.TempDir("", "localstore-src")
+	require.NoError(t, err)
+	defer os.RemoveAll(srcDir)
+
+	srcFile := filepath.Join(srcDir, "fileset-data.db")
+	require.NoError(t, ioutil.WriteFile(srcFile, []byte("some-block-data"), 0644))
+
+	s, err := New(root)
+	require.NoError(t, err)
+
+	blockStart := time.Unix(1465934820, 0)
+	// Deliberately wrong checksum: PutBlock records it verbatim, so GetBlock
+	// must catch the mismatch against the file's actual bytes on restore.
+	files := []remote.BlockFile{{Path: srcFile, Checksum: 42}}
+	require.NoError(t, s.PutBlock("metrics", 3, blockStart, files))
+
+	destDir, err := ioutil.TempDir("", "localstore-dest")
+	require.NoError(t, err)
+	defer os.RemoveAll(destDir)
+
+	_, err = s.GetBlock("metrics", 3, blockStart, destDir)
+	require.Error(t, err)
+}
+
+func TestListBlocksEmptyWhenNamespaceUnseen(t *testing.T) {
+	root, err := ioutil.TempDir("", "localstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	s, err := New(root)
+	require.NoError(t, err)
+
+	blockStarts, err := s.ListBlocks("unseen", 0)
+	require.NoError(t, err)
+	require.Empty(t, blockStarts)
+}
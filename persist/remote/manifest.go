@@ -0,0 +1,106 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"sync"
+	"time"
+)
+
+type manifestKey struct {
+	namespace  string
+	shard      uint32
+	blockStart int64
+}
+
+// Manifest tracks which blockStarts have already been uploaded to a
+// BackupStore, keyed by a checksum over the block's files, so a block that
+// changes on disk (e.g. re-sealed after a repair) is re-uploaded.
+type Manifest struct {
+	mu       sync.Mutex
+	uploaded map[manifestKey]uint32
+}
+
+// NewManifest creates an empty Manifest.
+func NewManifest() *Manifest {
+	return &Manifest{uploaded: make(map[manifestKey]uint32)}
+}
+
+// NamespaceShard identifies a single shard within a namespace.
+type NamespaceShard struct {
+	Namespace string
+	Shard     uint32
+}
+
+// NewManifestFromStore creates a Manifest pre-populated with every blockStart
+// already uploaded to store for the given namespace/shards, so a process
+// restart doesn't forget what it already backed up and re-upload its entire
+// sealed-block history from scratch.
+func NewManifestFromStore(store BackupStore, namespaceShards []NamespaceShard) (*Manifest, error) {
+	m := NewManifest()
+
+	for _, ns := range namespaceShards {
+		blockStarts, err := store.ListBlocks(ns.Namespace, ns.Shard)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, blockStart := range blockStarts {
+			checksum, err := store.BlockChecksum(ns.Namespace, ns.Shard, blockStart)
+			if err != nil {
+				return nil, err
+			}
+			m.MarkUploaded(ns.Namespace, ns.Shard, blockStart, checksum)
+		}
+	}
+
+	return m, nil
+}
+
+// IsUploaded returns whether blockStart has already been uploaded with the
+// given checksum.
+func (m *Manifest) IsUploaded(namespace string, shard uint32, blockStart time.Time, checksum uint32) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.uploaded[manifestKeyFor(namespace, shard, blockStart)]
+	return ok && existing == checksum
+}
+
+// MarkUploaded records blockStart as uploaded with the given checksum.
+func (m *Manifest) MarkUploaded(namespace string, shard uint32, blockStart time.Time, checksum uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.uploaded[manifestKeyFor(namespace, shard, blockStart)] = checksum
+}
+
+func manifestKeyFor(namespace string, shard uint32, blockStart time.Time) manifestKey {
+	return manifestKey{namespace: namespace, shard: shard, blockStart: blockStart.UnixNano()}
+}
+
+// ChecksumFiles combines the per-file checksums of a block's files into a
+// single manifest checksum.
+func ChecksumFiles(files []BlockFile) uint32 {
+	var combined uint32
+	for _, f := range files {
+		combined = combined*31 + f.Checksum
+	}
+	return combined
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackupStore struct {
+	BackupStore
+	blockStarts []time.Time
+	checksums   map[int64]uint32
+}
+
+func (f *fakeBackupStore) ListBlocks(namespace string, shard uint32) ([]time.Time, error) {
+	return f.blockStarts, nil
+}
+
+func (f *fakeBackupStore) BlockChecksum(namespace string, shard uint32, blockStart time.Time) (uint32, error) {
+	return f.checksums[blockStart.UnixNano()], nil
+}
+
+func TestNewManifestFromStoreSeedsUploaded(t *testing.T) {
+	blockStart := time.Unix(1465934820, 0)
+	store := &fakeBackupStore{
+		blockStarts: []time.Time{blockStart},
+		checksums:   map[int64]uint32{blockStart.UnixNano(): 7},
+	}
+
+	m, err := NewManifestFromStore(store, []NamespaceShard{{Namespace: "metrics", Shard: 1}})
+	require.NoError(t, err)
+	require.True(t, m.IsUploaded("metrics", 1, blockStart, 7))
+}
+
+func TestManifestSkipsUnchangedBlockStart(t *testing.T) {
+	m := NewManifest()
+	blockStart := time.Unix(1465934820, 0)
+	files := []BlockFile{{Path: "data.db", Checksum: 7}}
+
+	require.False(t, m.IsUploaded("metrics", 1, blockStart, ChecksumFiles(files)))
+
+	m.MarkUploaded("metrics", 1, blockStart, ChecksumFiles(files))
+	require.True(t, m.IsUploaded("metrics", 1, blockStart, ChecksumFiles(files)))
+
+	changed := []BlockFile{{Path: "data.db", Checksum: 8}}
+	require.False(t, m.IsUploaded("metrics", 1, blockStart, ChecksumFiles(changed)))
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+// HasLocalBlockFn reports whether a block is already present on local disk.
+type HasLocalBlockFn func(namespace string, shard uint32, blockStartUnixNanos int64) bool
+
+// DestDirFn returns the local directory a restored block's files should be
+// written to for namespace/shard.
+type DestDirFn func(namespace string, shard uint32) string
+
+// Bootstrapper restores missing blocks from a BackupStore when local disk
+// does not already have them, for use as a bootstrap source alongside the
+// existing fileset and peer bootstrappers.
+type Bootstrapper struct {
+	store      BackupStore
+	destDirFn  DestDirFn
+	hasLocalFn HasLocalBlockFn
+}
+
+// NewBootstrapper creates a new Bootstrapper.
+func NewBootstrapper(store BackupStore, destDirFn DestDirFn, hasLocalFn HasLocalBlockFn) *Bootstrapper {
+	return &Bootstrapper{store: store, destDirFn: destDirFn, hasLocalFn: hasLocalFn}
+}
+
+// Bootstrap fetches every block for namespace/shard known to the backup
+// store that is not already present on local disk.
+func (b *Bootstrapper) Bootstrap(namespace string, shard uint32) error {
+	blockStarts, err := b.store.ListBlocks(namespace, shard)
+	if err != nil {
+		return err
+	}
+
+	destDir := b.destDirFn(namespace, shard)
+	for _, blockStart := range blockStarts {
+		if b.hasLocalFn(namespace, shard, blockStart.UnixNano()) {
+			continue
+		}
+		if _, err := b.store.GetBlock(namespace, shard, blockStart, destDir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRestoreStore is a BackupStore with a fixed set of blocks, recording
+// which blockStarts GetBlock was actually called for.
+type fakeRestoreStore struct {
+	BackupStore
+
+	blockStarts []time.Time
+	fetched     []time.Time
+}
+
+func (s *fakeRestoreStore) ListBlocks(namespace string, shard uint32) ([]time.Time, error) {
+	return s.blockStarts, nil
+}
+
+func (s *fakeRestoreStore) GetBlock(namespace string, shard uint32, blockStart time.Time, destDir string) ([]string, error) {
+	s.fetched = append(s.fetched, blockStart)
+	return nil, nil
+}
+
+func TestBootstrapSkipsBlocksAlreadyOnLocalDisk(t *testing.T) {
+	present := time.Unix(1465934820, 0)
+	missing := time.Unix(1465934880, 0)
+	store := &fakeRestoreStore{blockStarts: []time.Time{present, missing}}
+
+	hasLocalFn := func(namespace string, shard uint32, blockStartUnixNanos int64) bool {
+		return blockStartUnixNanos == present.UnixNano()
+	}
+	destDirFn := func(namespace string, shard uint32) string { return "/data" }
+
+	b := NewBootstrapper(store, destDirFn, hasLocalFn)
+	require.NoError(t, b.Bootstrap("metrics", 1))
+
+	require.Equal(t, []time.Time{missing}, store.fetched)
+}
+
+func TestBootstrapPropagatesGetBlockError(t *testing.T) {
+	blockStart := time.Unix(1465934820, 0)
+	store := &failingGetBlockStore{blockStarts: []time.Time{blockStart}}
+
+	b := NewBootstrapper(store, func(string, uint32) string { return "/data" }, func(string, uint32, int64) bool { return false })
+	require.Error(t, b.Bootstrap("metrics", 1))
+}
+
+type failingGetBlockStore struct {
+	BackupStore
+	blockStarts []time.Time
+}
+
+func (s *failingGetBlockStore) ListBlocks(namespace string, shard uint32) ([]time.Time, error) {
+	return s.blockStarts, nil
+}
+
+func (s *failingGetBlockStore) GetBlock(namespace string, shard uint32, blockStart time.Time, destDir string) ([]string, error) {
+	return nil, errors.New("get block failed")
+}
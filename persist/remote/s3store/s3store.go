@@ -0,0 +1,224 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package s3store implements a remote.BackupStore backed by an S3-compatible
+// object store.
+package s3store
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3db/persist/remote"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+type store struct {
+	bucket   string
+	prefix   string
+	client   *s3.S3
+	uploader *s3manager.Uploader
+}
+
+// New creates a remote.BackupStore backed by the S3 bucket, with all object
+// keys namespaced under prefix.
+func New(sess *session.Session, bucket, prefix string) remote.BackupStore {
+	return &store{
+		bucket:   bucket,
+		prefix:   strings.TrimSuffix(prefix, "/"),
+		client:   s3.New(sess),
+		uploader: s3manager.NewUploader(sess),
+	}
+}
+
+func (s *store) key(namespace string, shard uint32, blockStart time.Time, name string) string {
+	return fmt.Sprintf("%s/%s/%d/%d/%s", s.prefix, namespace, shard, blockStart.UnixNano(), name)
+}
+
+func (s *store) PutBlock(namespace string, shard uint32, blockStart time.Time, files []remote.BlockFile) error {
+	for _, f := range files {
+		fd, err := os.Open(f.Path)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.uploader.Upload(&s3manager.UploadInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(s.key(namespace, shard, blockStart, filepath.Base(f.Path))),
+			Body:   fd,
+		})
+		fd.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.putObject(s.key(namespace, shard, blockStart, remote.FileManifestName), remote.EncodeFileManifest(files)); err != nil {
+		return err
+	}
+	return s.putObject(s.key(namespace, shard, blockStart, remote.BlockManifestName), remote.EncodeBlockChecksum(remote.ChecksumFiles(files)))
+}
+
+func (s *store) putObject(key string, data []byte) error {
+	_, err := s.uploader.Upload(&s3manager.UploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
+
+func (s *store) getObject(key string) ([]byte, error) {
+	out, err := s.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return ioutil.ReadAll(out.Body)
+}
+
+func (s *store) BlockChecksum(namespace string, shard uint32, blockStart time.Time) (uint32, error) {
+	data, err := s.getObject(s.key(namespace, shard, blockStart, remote.BlockManifestName))
+	if err != nil {
+		return 0, err
+	}
+	return remote.DecodeBlockChecksum(data)
+}
+
+func (s *store) ListBlocks(namespace string, shard uint32) ([]time.Time, error) {
+	prefix := fmt.Sprintf("%s/%s/%d/", s.prefix, namespace, shard)
+
+	seen := make(map[int64]struct{})
+	var blockStarts []time.Time
+
+	err := s.client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			parts := strings.Split(strings.TrimPrefix(*obj.Key, prefix), "/")
+			if len(parts) < 2 {
+				continue
+			}
+			nanos, err := strconv.ParseInt(parts[0], 10, 64)
+			if err != nil {
+				continue
+			}
+			if _, ok := seen[nanos]; ok {
+				continue
+			}
+			seen[nanos] = struct{}{}
+			blockStarts = append(blockStarts, time.Unix(0, nanos))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blockStarts, nil
+}
+
+func (s *store) GetBlock(namespace string, shard uint32, blockStart time.Time, destDir string) ([]string, error) {
+	prefix := fmt.Sprintf("%s/%s/%d/%d/", s.prefix, namespace, shard, blockStart.UnixNano())
+
+	manifestData, err := s.getObject(s.key(namespace, shard, blockStart, remote.FileManifestName))
+	if err != nil {
+		return nil, err
+	}
+	checksums, err := remote.DecodeFileManifest(manifestData)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, err
+	}
+
+	var restored []string
+	var pageErr error
+	err = s.client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			name := strings.TrimPrefix(*obj.Key, prefix)
+			if name == remote.FileManifestName || name == remote.BlockManifestName {
+				continue
+			}
+			destPath := filepath.Join(destDir, name)
+
+			out, err := s.client.GetObject(&s3.GetObjectInput{
+				Bucket: aws.String(s.bucket),
+				Key:    obj.Key,
+			})
+			if err != nil {
+				pageErr = err
+				return false
+			}
+
+			data, err := ioutil.ReadAll(out.Body)
+			out.Body.Close()
+			if err != nil {
+				pageErr = err
+				return false
+			}
+
+			checksum, ok := checksums[name]
+			if !ok {
+				pageErr = fmt.Errorf("s3store: no manifest entry for %q", name)
+				return false
+			}
+			if err := remote.VerifyChecksum(name, data, checksum); err != nil {
+				pageErr = err
+				return false
+			}
+
+			if err := ioutil.WriteFile(destPath, data, 0644); err != nil {
+				pageErr = err
+				return false
+			}
+
+			restored = append(restored, destPath)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if pageErr != nil {
+		return nil, pageErr
+	}
+
+	return restored, nil
+}
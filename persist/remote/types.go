@@ -0,0 +1,74 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package remote periodically uploads sealed block filesets to an
+// object-storage backed BackupStore and restores them on bootstrap when
+// local disk is empty.
+package remote
+
+import "time"
+
+// BlockFile describes a single file that makes up a block's fileset on
+// disk, as produced by persist/fs.
+type BlockFile struct {
+	// Path is the file's absolute path on local disk.
+	Path string
+
+	// Checksum is the file's content checksum, verified on restore.
+	Checksum uint32
+}
+
+// BackupStore persists and retrieves sealed block filesets to/from an
+// object-storage backend. Implementations must be safe for concurrent use.
+type BackupStore interface {
+	// PutBlock uploads the given files for namespace/shard/blockStart.
+	PutBlock(namespace string, shard uint32, blockStart time.Time, files []BlockFile) error
+
+	// ListBlocks returns the block starts already uploaded for namespace/shard.
+	ListBlocks(namespace string, shard uint32) ([]time.Time, error)
+
+	// GetBlock downloads the fileset for namespace/shard/blockStart into
+	// destDir, returning the local paths of the restored files. Every
+	// downloaded file's checksum is verified against the manifest recorded
+	// by PutBlock; GetBlock returns an error if a file has no manifest entry
+	// or fails that check (e.g. it is truncated). A manifest entry with no
+	// matching file in the store is not reported, and files written to
+	// destDir before an error are left in place.
+	GetBlock(namespace string, shard uint32, blockStart time.Time, destDir string) ([]string, error)
+
+	// BlockChecksum returns the aggregate checksum PutBlock recorded for
+	// namespace/shard/blockStart, so a Manifest can be reconstructed from
+	// what the store already has on a fresh process start.
+	BlockChecksum(namespace string, shard uint32, blockStart time.Time) (uint32, error)
+}
+
+// BackupConfig configures the periodic block backup subsystem.
+type BackupConfig struct {
+	// Interval is how often sealed blocks are scanned for upload, zero disables backups.
+	Interval time.Duration
+
+	// MaxRetries is how many times a failed upload is retried, mirroring
+	// dbOptions.maxFlushRetries semantics.
+	MaxRetries int
+}
+
+// NewBackupStoreFn constructs a BackupStore, allowing the driver
+// (S3, GCS, local dir, ...) to be swapped without dbOptions knowing about it.
+type NewBackupStoreFn func() (BackupStore, error)
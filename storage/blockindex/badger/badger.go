@@ -0,0 +1,250 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package badger implements blockindex.Store on top of an embedded Badger
+// LSM-tree key/value store.
+package badger
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3db/storage/blockindex"
+
+	"github.com/dgraph-io/badger"
+)
+
+const (
+	// DefaultGCInterval is how often value-log GC runs by default.
+	DefaultGCInterval = 10 * time.Minute
+
+	// defaultGCDiscardRatio is the minimum ratio of reclaimable space badger
+	// requires before it will rewrite a value log file.
+	defaultGCDiscardRatio = 0.5
+)
+
+var errNotOpen = errors.New("badger: store is not open")
+
+// Options configures a badger-backed blockindex.Store.
+type Options struct {
+	// Dir is the directory badger stores its LSM tree and value logs in.
+	Dir string
+
+	// GCInterval is how often RunValueLogGC is invoked, zero uses
+	// DefaultGCInterval and a negative value disables GC.
+	GCInterval time.Duration
+}
+
+type store struct {
+	opts   Options
+	db     *badger.DB
+	stopGC chan struct{}
+}
+
+// NewStore creates a blockindex.Store backed by Badger. Callers must call
+// Open before using the store and Close when done with it.
+func NewStore(opts Options) blockindex.Store {
+	if opts.GCInterval == 0 {
+		opts.GCInterval = DefaultGCInterval
+	}
+	return &store{opts: opts}
+}
+
+func (s *store) Open() error {
+	badgerOpts := badger.DefaultOptions
+	badgerOpts.Dir = s.opts.Dir
+	badgerOpts.ValueDir = s.opts.Dir
+
+	db, err := badger.Open(badgerOpts)
+	if err != nil {
+		return err
+	}
+	s.db = db
+
+	if s.opts.GCInterval > 0 {
+		s.stopGC = make(chan struct{})
+		go s.runGC()
+	}
+
+	return nil
+}
+
+func (s *store) Close() error {
+	if s.stopGC != nil {
+		close(s.stopGC)
+	}
+	if s.db == nil {
+		return errNotOpen
+	}
+	return s.db.Close()
+}
+
+func (s *store) runGC() {
+	ticker := time.NewTicker(s.opts.GCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// NB(r): badger returns ErrNoRewrite when there's nothing to
+			// reclaim, that's the common case and not worth surfacing.
+			_ = s.db.RunValueLogGC(defaultGCDiscardRatio)
+		case <-s.stopGC:
+			return
+		}
+	}
+}
+
+func (s *store) Put(namespace string, shard uint32, id string, blockStart time.Time, offset, length int64) error {
+	if s.db == nil {
+		return errNotOpen
+	}
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key(namespace, shard, id, blockStart), value(offset, length))
+	})
+}
+
+func (s *store) Get(namespace string, shard uint32, id string, blockStart time.Time) (blockindex.Entry, bool, error) {
+	if s.db == nil {
+		return blockindex.Entry{}, false, errNotOpen
+	}
+
+	var entry blockindex.Entry
+	var found bool
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key(namespace, shard, id, blockStart))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		v, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+
+		offset, length := parseValue(v)
+		entry = blockindex.Entry{BlockStart: blockStart, Offset: offset, Length: length}
+		found = true
+		return nil
+	})
+
+	return entry, found, err
+}
+
+func (s *store) Range(namespace string, shard uint32, start, end time.Time, fn func(id string, entry blockindex.Entry) bool) error {
+	if s.db == nil {
+		return errNotOpen
+	}
+
+	prefix := []byte(fmt.Sprintf("%s/%d/", namespace, shard))
+
+	return s.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+
+			id, blockStart, ok := parseKey(item.Key(), prefix)
+			if !ok || blockStart.Before(start) || !blockStart.Before(end) {
+				continue
+			}
+
+			v, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+
+			offset, length := parseValue(v)
+			entry := blockindex.Entry{BlockStart: blockStart, Offset: offset, Length: length}
+			if !fn(id, entry) {
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *store) Delete(namespace string, shard uint32, id string, blockStart time.Time) error {
+	if s.db == nil {
+		return errNotOpen
+	}
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(key(namespace, shard, id, blockStart))
+	})
+}
+
+// WriteBatch applies every write in a single badger transaction so a crash
+// mid-flush leaves either all or none of the block's index entries.
+func (s *store) WriteBatch(writes []blockindex.BatchWrite) error {
+	if s.db == nil {
+		return errNotOpen
+	}
+	return s.db.Update(func(txn *badger.Txn) error {
+		for _, w := range writes {
+			k := key(w.Namespace, w.Shard, w.ID, w.BlockStart)
+			if err := txn.Set(k, value(w.Offset, w.Length)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func key(namespace string, shard uint32, id string, blockStart time.Time) []byte {
+	return []byte(fmt.Sprintf("%s/%d/%s/%d", namespace, shard, id, blockStart.UnixNano()))
+}
+
+func parseKey(k []byte, prefix []byte) (id string, blockStart time.Time, ok bool) {
+	rest := strings.TrimPrefix(string(k), string(prefix))
+	idx := strings.LastIndex(rest, "/")
+	if idx < 0 {
+		return "", time.Time{}, false
+	}
+
+	var nanos int64
+	if _, err := fmt.Sscanf(rest[idx+1:], "%d", &nanos); err != nil {
+		return "", time.Time{}, false
+	}
+
+	return rest[:idx], time.Unix(0, nanos), true
+}
+
+func value(offset, length int64) []byte {
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf[0:8], uint64(offset))
+	binary.BigEndian.PutUint64(buf[8:16], uint64(length))
+	return buf
+}
+
+func parseValue(v []byte) (offset, length int64) {
+	if len(v) != 16 {
+		return 0, 0
+	}
+	return int64(binary.BigEndian.Uint64(v[0:8])), int64(binary.BigEndian.Uint64(v[8:16]))
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package blockindex
+
+import "time"
+
+// FilesetEntry describes a single series' block location as discovered by
+// scanning an existing fileset on disk.
+type FilesetEntry struct {
+	Namespace  string
+	Shard      uint32
+	ID         string
+	BlockStart time.Time
+	Offset     int64
+	Length     int64
+}
+
+// ScanFilesetsFn scans the fileset files already on local disk and returns
+// every series' block location found there.
+type ScanFilesetsFn func() ([]FilesetEntry, error)
+
+// maxMigrateBatchSize bounds the number of entries applied per WriteBatch
+// call. Store implementations back WriteBatch with a single atomic
+// transaction (e.g. a badger.Txn), and those have a bounded max size and
+// entry count, so a first-boot migration over a node with a large existing
+// series/block count must be chunked rather than applied as one batch.
+const maxMigrateBatchSize = 1000
+
+// Migrate populates store with every entry scanFn discovers on local disk,
+// for use the first time a node boots with a Store configured so existing
+// filesets don't appear to have no series indexed. Entries are applied in
+// chunks of at most maxMigrateBatchSize, each as its own atomic batch; a
+// crash partway through leaves the chunks applied so far in store and
+// Migrate can simply be re-run, since WriteBatch is idempotent for
+// unchanged entries.
+func Migrate(store Store, scanFn ScanFilesetsFn) error {
+	entries, err := scanFn()
+	if err != nil {
+		return err
+	}
+
+	writes := make([]BatchWrite, 0, maxMigrateBatchSize)
+	for _, e := range entries {
+		writes = append(writes, BatchWrite{
+			Namespace:  e.Namespace,
+			Shard:      e.Shard,
+			ID:         e.ID,
+			BlockStart: e.BlockStart,
+			Offset:     e.Offset,
+			Length:     e.Length,
+		})
+
+		if len(writes) == maxMigrateBatchSize {
+			if err := store.WriteBatch(writes); err != nil {
+				return err
+			}
+			writes = writes[:0]
+		}
+	}
+
+	if len(writes) > 0 {
+		return store.WriteBatch(writes)
+	}
+
+	return nil
+}
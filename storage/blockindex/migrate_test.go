@@ -0,0 +1,77 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package blockindex
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	Store
+	batches [][]BatchWrite
+	failAt  int
+}
+
+func (f *fakeStore) WriteBatch(writes []BatchWrite) error {
+	if f.failAt > 0 && len(f.batches) == f.failAt-1 {
+		return errors.New("write batch failed")
+	}
+	batch := make([]BatchWrite, len(writes))
+	copy(batch, writes)
+	f.batches = append(f.batches, batch)
+	return nil
+}
+
+func entriesFn(n int) ScanFilesetsFn {
+	return func() ([]FilesetEntry, error) {
+		entries := make([]FilesetEntry, 0, n)
+		for i := 0; i < n; i++ {
+			entries = append(entries, FilesetEntry{
+				Namespace:  "metrics",
+				Shard:      0,
+				ID:         "series",
+				BlockStart: time.Unix(1465934820, 0),
+			})
+		}
+		return entries, nil
+	}
+}
+
+func TestMigrateChunksLargeScansIntoBoundedBatches(t *testing.T) {
+	store := &fakeStore{}
+	require.NoError(t, Migrate(store, entriesFn(maxMigrateBatchSize*2+1)))
+
+	require.Len(t, store.batches, 3)
+	require.Len(t, store.batches[0], maxMigrateBatchSize)
+	require.Len(t, store.batches[1], maxMigrateBatchSize)
+	require.Len(t, store.batches[2], 1)
+}
+
+func TestMigrateStopsOnFirstBatchError(t *testing.T) {
+	store := &fakeStore{failAt: 2}
+	err := Migrate(store, entriesFn(maxMigrateBatchSize*2))
+	require.Error(t, err)
+	require.Len(t, store.batches, 1)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package blockindex defines the pluggable series/block lookup index used by
+// storage, decoupling it from the filesystem-oriented fileset writer.
+package blockindex
+
+import "time"
+
+// Entry locates a single series' block within a fileset.
+type Entry struct {
+	BlockStart time.Time
+	Offset     int64
+	Length     int64
+}
+
+// BatchWrite is a single index mutation passed to Store.WriteBatch. All the
+// BatchWrites of one WriteBatch call are applied together or not at all so
+// a crash mid-flush cannot leave a block with a partial set of index entries.
+type BatchWrite struct {
+	Namespace  string
+	Shard      uint32
+	ID         string
+	BlockStart time.Time
+	Offset     int64
+	Length     int64
+}
+
+// Store is a pluggable series/block lookup index. Implementations must be
+// safe for concurrent use.
+type Store interface {
+	// Put records where a series' block lives within a fileset.
+	Put(namespace string, shard uint32, id string, blockStart time.Time, offset, length int64) error
+
+	// Get looks up a single series' block entry, ok is false if absent.
+	Get(namespace string, shard uint32, id string, blockStart time.Time) (entry Entry, ok bool, err error)
+
+	// Range iterates every entry for namespace/shard with a blockStart in
+	// [start, end), calling fn for each until it returns false or the range
+	// is exhausted.
+	Range(namespace string, shard uint32, start, end time.Time, fn func(id string, entry Entry) bool) error
+
+	// Delete removes a single series' block entry.
+	Delete(namespace string, shard uint32, id string, blockStart time.Time) error
+
+	// WriteBatch atomically applies every write in the batch, matching a
+	// single flush's worth of index mutations.
+	WriteBatch(writes []BatchWrite) error
+
+	// Open opens the store and begins any background maintenance (e.g.
+	// value-log GC) the implementation requires.
+	Open() error
+
+	// Close stops background maintenance and releases the store's resources.
+	Close() error
+}
@@ -25,9 +25,13 @@ import (
 	"time"
 
 	"github.com/m3db/m3db/encoding/tsz"
+	"github.com/m3db/m3db/ingest/graphite"
+	"github.com/m3db/m3db/ingest/kafka"
 	"github.com/m3db/m3db/interfaces/m3db"
 	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/persist/remote"
 	"github.com/m3db/m3db/pool"
+	"github.com/m3db/m3db/storage/blockindex"
 	"github.com/m3db/m3db/x/logging"
 	"github.com/m3db/m3db/x/metrics"
 )
@@ -92,6 +96,11 @@ type dbOptions struct {
 	maxFlushRetries          int
 	filePathPrefix           string
 	newFileSetWriterFn       m3db.NewFileSetWriterFn
+	ingestConfig             graphite.IngestConfig
+	kafkaIngestConfig        kafka.IngestConfig
+	backupConfig             remote.BackupConfig
+	newBackupStoreFn         remote.NewBackupStoreFn
+	blockIndexStore          blockindex.Store
 }
 
 // NewDatabaseOptions creates a new set of database options with defaults
@@ -405,4 +414,146 @@ func (o *dbOptions) NewFileSetWriterFn(value m3db.NewFileSetWriterFn) m3db.Datab
 
 func (o *dbOptions) GetNewFileSetWriterFn() m3db.NewFileSetWriterFn {
 	return o.newFileSetWriterFn
-}
\ No newline at end of file
+}
+
+// IngestConfig sets the Graphite ingestion configuration wholesale.
+func (o *dbOptions) IngestConfig(value graphite.IngestConfig) m3db.DatabaseOptions {
+	opts := *o
+	opts.ingestConfig = value
+	return &opts
+}
+
+// GetIngestConfig returns the Graphite ingestion configuration.
+func (o *dbOptions) GetIngestConfig() graphite.IngestConfig {
+	return o.ingestConfig
+}
+
+// GraphiteListenAddr sets the address the Graphite plaintext listener binds
+// to, an empty value leaves the listener disabled.
+func (o *dbOptions) GraphiteListenAddr(value string) m3db.DatabaseOptions {
+	opts := *o
+	opts.ingestConfig.ListenAddr = value
+	return &opts
+}
+
+// GetGraphiteListenAddr returns the configured Graphite plaintext listen address.
+func (o *dbOptions) GetGraphiteListenAddr() string {
+	return o.ingestConfig.ListenAddr
+}
+
+// GraphitePickleAddr sets the address the Graphite pickle listener binds to,
+// an empty value leaves the listener disabled.
+func (o *dbOptions) GraphitePickleAddr(value string) m3db.DatabaseOptions {
+	opts := *o
+	opts.ingestConfig.PickleAddr = value
+	return &opts
+}
+
+// GetGraphitePickleAddr returns the configured Graphite pickle listen address.
+func (o *dbOptions) GetGraphitePickleAddr() string {
+	return o.ingestConfig.PickleAddr
+}
+
+// GraphiteReadTimeout sets how long an accepted Graphite connection may sit
+// idle before being closed. Zero disables the deadline.
+func (o *dbOptions) GraphiteReadTimeout(value time.Duration) m3db.DatabaseOptions {
+	opts := *o
+	opts.ingestConfig.ReadTimeout = value
+	return &opts
+}
+
+// GetGraphiteReadTimeout returns the configured Graphite connection read timeout.
+func (o *dbOptions) GetGraphiteReadTimeout() time.Duration {
+	return o.ingestConfig.ReadTimeout
+}
+
+// KafkaBrokers sets the seed Kafka broker addresses, empty leaves Kafka
+// ingestion disabled.
+func (o *dbOptions) KafkaBrokers(value []string) m3db.DatabaseOptions {
+	opts := *o
+	opts.kafkaIngestConfig.Brokers = value
+	return &opts
+}
+
+// GetKafkaBrokers returns the configured Kafka seed broker addresses.
+func (o *dbOptions) GetKafkaBrokers() []string {
+	return o.kafkaIngestConfig.Brokers
+}
+
+// KafkaTopics sets the topics the Kafka consumer group subscribes to.
+func (o *dbOptions) KafkaTopics(value []string) m3db.DatabaseOptions {
+	opts := *o
+	opts.kafkaIngestConfig.Topics = value
+	return &opts
+}
+
+// GetKafkaTopics returns the configured Kafka topics.
+func (o *dbOptions) GetKafkaTopics() []string {
+	return o.kafkaIngestConfig.Topics
+}
+
+// KafkaGroupID sets the Kafka consumer group ID, shared across all m3db
+// nodes ingesting the same topics so partitions are divided between them.
+func (o *dbOptions) KafkaGroupID(value string) m3db.DatabaseOptions {
+	opts := *o
+	opts.kafkaIngestConfig.GroupID = value
+	return &opts
+}
+
+// GetKafkaGroupID returns the configured Kafka consumer group ID.
+func (o *dbOptions) GetKafkaGroupID() string {
+	return o.kafkaIngestConfig.GroupID
+}
+
+// KafkaMessageDecoderFn sets the function used to decode a raw Kafka message
+// into a single sample.
+func (o *dbOptions) KafkaMessageDecoderFn(value kafka.MessageDecoderFn) m3db.DatabaseOptions {
+	opts := *o
+	opts.kafkaIngestConfig.MessageDecoderFn = value
+	return &opts
+}
+
+// GetKafkaMessageDecoderFn returns the configured Kafka message decoder function.
+func (o *dbOptions) GetKafkaMessageDecoderFn() kafka.MessageDecoderFn {
+	return o.kafkaIngestConfig.MessageDecoderFn
+}
+
+// BackupConfig sets the periodic block backup configuration.
+func (o *dbOptions) BackupConfig(value remote.BackupConfig) m3db.DatabaseOptions {
+	opts := *o
+	opts.backupConfig = value
+	return &opts
+}
+
+// GetBackupConfig returns the configured block backup configuration.
+func (o *dbOptions) GetBackupConfig() remote.BackupConfig {
+	return o.backupConfig
+}
+
+// NewBackupStoreFn sets the function used to construct the BackupStore that
+// sealed blocks are uploaded to and restored from.
+func (o *dbOptions) NewBackupStoreFn(value remote.NewBackupStoreFn) m3db.DatabaseOptions {
+	opts := *o
+	opts.newBackupStoreFn = value
+	return &opts
+}
+
+// GetNewBackupStoreFn returns the configured BackupStore constructor.
+func (o *dbOptions) GetNewBackupStoreFn() remote.NewBackupStoreFn {
+	return o.newBackupStoreFn
+}
+
+// BlockIndexStore sets the pluggable series/block lookup index returned by
+// GetBlockIndexStore. The storage lookup path in this package does not
+// read it; series lookups still go through the in-memory + fileset path.
+func (o *dbOptions) BlockIndexStore(value blockindex.Store) m3db.DatabaseOptions {
+	opts := *o
+	opts.blockIndexStore = value
+	return &opts
+}
+
+// GetBlockIndexStore returns the configured series/block lookup index, nil
+// if none is set.
+func (o *dbOptions) GetBlockIndexStore() blockindex.Store {
+	return o.blockIndexStore
+}